@@ -0,0 +1,499 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/codes"
+	jaegerexporter "go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	trace "go.opentelemetry.io/otel/trace"
+)
+
+type Tracer interface {
+	Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, Span)
+	// GetTracer returns a Tracer scoped to the given instrumentation library name
+	// and version, so spans emitted through it show up under that scope
+	// (rather than under a single catch-all "component-main") in the backend.
+	GetTracer(name, version string) Tracer
+	// Health reports the most recent exporter error, if any, so callers (e.g.
+	// a readiness probe) can surface a degraded tracing pipeline.
+	Health() error
+	// ForceFlush flushes all pending spans through every configured exporter.
+	// HTTP handlers and short-lived grafana-cli commands should call this
+	// before they return to avoid losing spans buffered by the batcher.
+	ForceFlush(ctx context.Context) error
+	Run(context.Context) error
+}
+
+type Span interface {
+	End()
+	SetAttributes(kv ...attribute.KeyValue)
+	// RecordError records err as an exception event on the span.
+	RecordError(err error, opts ...trace.EventOption)
+	// SetStatus sets the span's status, e.g. codes.Error once a handler knows
+	// a request failed.
+	SetStatus(code codes.Code, description string)
+	// AddEvent adds a timestamped event with optional attributes to the span.
+	AddEvent(name string, attrs ...attribute.KeyValue)
+	// SpanContext returns the span's SpanContext, e.g. so a caller can read
+	// the trace id to include in a log line or error response.
+	SpanContext() trace.SpanContext
+}
+
+// GlobalTracer is the tracer used by subsystems that have not yet migrated to
+// a scoped Tracer obtained via Tracer.GetTracer.
+//
+// Deprecated: call GetTracer(name, version) on the configured Tracer and keep
+// the result instead of depending on this package-level variable.
+var GlobalTracer trace.Tracer
+
+// otlpExporterSettings holds the configuration shared by the gRPC and HTTP
+// flavors of the OTLP exporter.
+type otlpExporterSettings struct {
+	enabled  bool
+	endpoint string
+	insecure bool
+}
+
+// batchSettings mirrors the tracesdk.BatchSpanProcessorOptions Grafana
+// exposes through [tracing.opentelemetry], so operators can tune the batcher
+// for their traffic pattern instead of living with the SDK defaults.
+type batchSettings struct {
+	maxQueueSize       int
+	maxExportBatchSize int
+	batchTimeout       time.Duration
+	exportTimeout      time.Duration
+}
+
+func (b batchSettings) asOptions() []tracesdk.BatchSpanProcessorOption {
+	return []tracesdk.BatchSpanProcessorOption{
+		tracesdk.WithMaxQueueSize(b.maxQueueSize),
+		tracesdk.WithMaxExportBatchSize(b.maxExportBatchSize),
+		tracesdk.WithBatchTimeout(b.batchTimeout),
+		tracesdk.WithExportTimeout(b.exportTimeout),
+	}
+}
+
+var tracingExporterErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grafana_tracing_exporter_errors_total",
+	Help: "The number of errors encountered while exporting spans, by exporter and reason",
+}, []string{"exporter", "reason"})
+
+// tracingHealth tracks the most recent error any exporter reported, so it can
+// be surfaced through Tracer.Health without each exporter having to know
+// about the others.
+type tracingHealth struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (h *tracingHealth) recordError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+}
+
+func (h *tracingHealth) Health() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// healthTrackingExporter wraps a tracesdk.SpanExporter so export errors are
+// counted per exporter and recorded on the shared tracingHealth.
+type healthTrackingExporter struct {
+	name   string
+	exp    tracesdk.SpanExporter
+	health *tracingHealth
+}
+
+func (e *healthTrackingExporter) ExportSpans(ctx context.Context, spans []tracesdk.ReadOnlySpan) error {
+	err := e.exp.ExportSpans(ctx, spans)
+	if err != nil {
+		tracingExporterErrorsTotal.WithLabelValues(e.name, "export").Inc()
+		e.health.recordError(fmt.Errorf("%s exporter: %w", e.name, err))
+	}
+	return err
+}
+
+func (e *healthTrackingExporter) Shutdown(ctx context.Context) error {
+	if err := e.exp.Shutdown(ctx); err != nil {
+		tracingExporterErrorsTotal.WithLabelValues(e.name, "shutdown").Inc()
+		e.health.recordError(fmt.Errorf("%s exporter: %w", e.name, err))
+		return err
+	}
+	return nil
+}
+
+// TracingService is the single OTel-based implementation of Tracer. It
+// replaces the former split between an OpenTelemetry path and a parallel
+// opentracing-go path: every span is created through the OTel SDK, and an
+// opentracing.Tracer bridged from the same TracerProvider (via
+// go.opentelemetry.io/otel/bridge/opentracing) is installed globally so
+// in-tree code that still calls opentracing.StartSpanFromContext keeps
+// working against the same pipeline.
+type TracingService struct {
+	enabled bool
+	address string
+	log     log.Logger
+
+	jaegerEnabled bool
+	otlpGRPC      otlpExporterSettings
+	otlpHTTP      otlpExporterSettings
+	stdoutEnabled bool
+
+	serviceName    string
+	serviceVersion string
+	environment    string
+	instanceID     string
+
+	sampler      string
+	samplerParam float64
+	propagators  []string
+
+	batch batchSettings
+
+	tracerProvider *tracesdk.TracerProvider
+	// tracer is the scoped trace.Tracer this service (or a GetTracer-derived
+	// copy of it) hands out spans from.
+	tracer trace.Tracer
+	health *tracingHealth
+
+	Cfg *setting.Cfg
+}
+
+type OpentelemetrySpan struct {
+	span trace.Span
+}
+
+func (ts *TracingService) parseSettingsOpentelemetry() error {
+	jaegerSection, err := ts.Cfg.Raw.GetSection("tracing.opentelemetry.jaeger")
+	if err != nil {
+		return err
+	}
+	ts.jaegerEnabled = jaegerSection.Key("enabled").MustBool(false)
+	ts.address = jaegerSection.Key("address").MustString("")
+
+	otlpSection, err := ts.Cfg.Raw.GetSection("tracing.opentelemetry.otlp")
+	if err != nil {
+		return err
+	}
+	ts.otlpGRPC.enabled = otlpSection.Key("grpc_enabled").MustBool(false)
+	ts.otlpGRPC.endpoint = otlpSection.Key("grpc_address").MustString("localhost:4317")
+	ts.otlpGRPC.insecure = otlpSection.Key("grpc_insecure").MustBool(true)
+	ts.otlpHTTP.enabled = otlpSection.Key("http_enabled").MustBool(false)
+	ts.otlpHTTP.endpoint = otlpSection.Key("http_address").MustString("localhost:4318")
+	ts.otlpHTTP.insecure = otlpSection.Key("http_insecure").MustBool(true)
+
+	stdoutSection, err := ts.Cfg.Raw.GetSection("tracing.opentelemetry.stdout")
+	if err != nil {
+		return err
+	}
+	ts.stdoutEnabled = stdoutSection.Key("enabled").MustBool(false)
+
+	commonSection, err := ts.Cfg.Raw.GetSection("tracing.opentelemetry")
+	if err != nil {
+		return err
+	}
+	ts.serviceName = commonSection.Key("service_name").MustString("grafana")
+	ts.serviceVersion = commonSection.Key("service_version").MustString(ts.Cfg.BuildVersion)
+	ts.environment = commonSection.Key("environment").MustString(string(ts.Cfg.Env))
+	ts.instanceID = commonSection.Key("instance_id").MustString(hostnameOrUnknown())
+
+	ts.sampler = commonSection.Key("sampler").MustString("always_on")
+	ts.samplerParam = commonSection.Key("sampler_param").MustFloat64(1.0)
+	ts.propagators = splitAndTrim(commonSection.Key("propagation").MustString("tracecontext,baggage"))
+
+	ts.batch.maxQueueSize = commonSection.Key("max_queue_size").MustInt(2048)
+	ts.batch.maxExportBatchSize = commonSection.Key("max_export_batch_size").MustInt(512)
+	ts.batch.batchTimeout = commonSection.Key("batch_timeout").MustDuration(5 * time.Second)
+	ts.batch.exportTimeout = commonSection.Key("export_timeout").MustDuration(30 * time.Second)
+
+	ts.enabled = ts.jaegerEnabled || ts.otlpGRPC.enabled || ts.otlpHTTP.enabled || ts.stdoutEnabled
+
+	return nil
+}
+
+func splitAndTrim(s string) []string {
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// buildSampler translates the `sampler` / `sampler_param` settings into a
+// tracesdk.Sampler. Unknown values fall back to always_on so tracing never
+// silently stops working because of a config typo.
+func (ts *TracingService) buildSampler() tracesdk.Sampler {
+	switch ts.sampler {
+	case "always_off":
+		return tracesdk.NeverSample()
+	case "traceidratio":
+		return tracesdk.TraceIDRatioBased(ts.samplerParam)
+	case "parentbased_traceidratio":
+		return tracesdk.ParentBased(tracesdk.TraceIDRatioBased(ts.samplerParam))
+	case "always_on", "":
+		return tracesdk.AlwaysSample()
+	default:
+		ts.log.Warn("Unknown tracing sampler, falling back to always_on", "sampler", ts.sampler)
+		return tracesdk.AlwaysSample()
+	}
+}
+
+// buildPropagator composes the configured `propagation` keys into a single
+// propagation.TextMapPropagator, so Grafana can both emit and accept headers
+// from upstream/downstream services that don't speak the W3C default.
+func (ts *TracingService) buildPropagator() propagation.TextMapPropagator {
+	var propagators []propagation.TextMapPropagator
+	for _, p := range ts.propagators {
+		switch p {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New())
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			ts.log.Warn("Unknown tracing propagator, ignoring", "propagator", p)
+		}
+	}
+	if len(propagators) == 0 {
+		return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
+
+func hostnameOrUnknown() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}
+
+func (ts *TracingService) buildResource() *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(ts.serviceName),
+		semconv.ServiceVersionKey.String(ts.serviceVersion),
+		semconv.ServiceInstanceIDKey.String(ts.instanceID),
+		attribute.String("environment", ts.environment),
+	)
+}
+
+// buildSpanProcessors creates a batching tracesdk.SpanProcessor for every
+// exporter that is enabled in configuration, so spans can be shipped to
+// several backends (e.g. Tempo via OTLP and stdout for local debugging) at
+// the same time.
+func (ts *TracingService) buildSpanProcessors() ([]tracesdk.SpanProcessor, error) {
+	var processors []tracesdk.SpanProcessor
+	batchOpts := ts.batch.asOptions()
+
+	wrap := func(name string, exp tracesdk.SpanExporter) tracesdk.SpanProcessor {
+		tracked := &healthTrackingExporter{name: name, exp: exp, health: ts.health}
+		return tracesdk.NewBatchSpanProcessor(tracked, batchOpts...)
+	}
+
+	if ts.jaegerEnabled {
+		exp, err := jaegerexporter.New(jaegerexporter.WithCollectorEndpoint(jaegerexporter.WithEndpoint(ts.address)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create jaeger exporter: %w", err)
+		}
+		processors = append(processors, wrap("jaeger", exp))
+	}
+
+	if ts.otlpGRPC.enabled {
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(ts.otlpGRPC.endpoint)}
+		if ts.otlpGRPC.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exp, err := otlptracegrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp grpc exporter: %w", err)
+		}
+		processors = append(processors, wrap("otlp_grpc", exp))
+	}
+
+	if ts.otlpHTTP.enabled {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(ts.otlpHTTP.endpoint)}
+		if ts.otlpHTTP.insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp http exporter: %w", err)
+		}
+		processors = append(processors, wrap("otlp_http", exp))
+	}
+
+	if ts.stdoutEnabled {
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		processors = append(processors, wrap("stdout", exp))
+	}
+
+	return processors, nil
+}
+
+func (ts *TracingService) initTracerProvider() (*tracesdk.TracerProvider, error) {
+	processors, err := ts.buildSpanProcessors()
+	if err != nil {
+		return nil, err
+	}
+
+	tpOpts := []tracesdk.TracerProviderOption{
+		// Record information about this application in a Resource.
+		tracesdk.WithResource(ts.buildResource()),
+		tracesdk.WithSampler(ts.buildSampler()),
+	}
+	for _, p := range processors {
+		// Always be sure to batch in production.
+		tpOpts = append(tpOpts, tracesdk.WithSpanProcessor(p))
+	}
+
+	tp := tracesdk.NewTracerProvider(tpOpts...)
+
+	return tp, nil
+}
+
+// initOpentelemetryTracer builds the TracerProvider and, on top of it, bridges
+// an opentracing.Tracer via go.opentelemetry.io/otel/bridge/opentracing so
+// legacy opentracing.StartSpanFromContext callers keep working without a
+// second, independent tracing pipeline.
+func (ts *TracingService) initOpentelemetryTracer() error {
+	ts.health = &tracingHealth{}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		tracingExporterErrorsTotal.WithLabelValues("unknown", "internal").Inc()
+		ts.health.recordError(err)
+		ts.log.Error("Tracing error", "error", err)
+	}))
+
+	tp, err := ts.initTracerProvider()
+	if err != nil {
+		return err
+	}
+	ts.tracerProvider = tp
+
+	if ts.enabled {
+		otel.SetTextMapPropagator(ts.buildPropagator())
+	}
+
+	ts.tracer = tp.Tracer("component-main")
+
+	// Deprecated: kept in sync for subsystems that have not migrated to
+	// GetTracer yet.
+	GlobalTracer = ts.tracer
+
+	// bridgeTracer lets legacy opentracing.StartSpanFromContext callers keep
+	// working. wrapperProvider must be installed as the global TracerProvider
+	// (not the raw tp) so that spans started through the global OTel API and
+	// spans started through the opentracing bridge share "current span"
+	// state instead of diverging into two unrelated trees.
+	bridgeTracer, wrapperProvider := otelbridge.NewTracerPair(ts.tracer)
+	opentracing.SetGlobalTracer(bridgeTracer)
+	if ts.enabled {
+		otel.SetTracerProvider(wrapperProvider)
+	}
+
+	return nil
+}
+
+// GetTracer returns a copy of this service scoped to the given instrumentation
+// library name and version, so spans it emits are attributed to that
+// subsystem instead of the catch-all "component-main" scope.
+func (ts *TracingService) GetTracer(name, version string) Tracer {
+	scoped := *ts
+	scoped.tracer = ts.tracerProvider.Tracer(name, trace.WithInstrumentationVersion(version))
+	return &scoped
+}
+
+// Health reports the most recent error encountered by any configured
+// exporter, or nil if none has failed.
+func (ts *TracingService) Health() error {
+	return ts.health.Health()
+}
+
+// ForceFlush flushes all spans buffered by the batchers of every configured
+// exporter. Callers that are about to exit (HTTP handlers serving a single
+// request, grafana-cli commands) should call this before Run's Shutdown has
+// a chance to run, so in-flight spans aren't dropped.
+func (ts *TracingService) ForceFlush(ctx context.Context) error {
+	return ts.tracerProvider.ForceFlush(ctx)
+}
+
+func (ts *TracingService) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	ts.log.Info("Closing tracing")
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := ts.ForceFlush(ctxShutdown); err != nil {
+		ts.log.Warn("Failed to flush traces before shutdown", "error", err)
+	}
+	if err := ts.tracerProvider.Shutdown(ctxShutdown); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ts *TracingService) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, Span) {
+	ctx, span := ts.tracer.Start(ctx, spanName, opts...)
+	oSpan := OpentelemetrySpan{span: span}
+	return ctx, oSpan
+}
+
+func (s OpentelemetrySpan) End() {
+	s.span.End()
+}
+
+func (s OpentelemetrySpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.span.SetAttributes(kv...)
+}
+
+func (s OpentelemetrySpan) RecordError(err error, opts ...trace.EventOption) {
+	s.span.RecordError(err, opts...)
+}
+
+func (s OpentelemetrySpan) SetStatus(code codes.Code, description string) {
+	s.span.SetStatus(code, description)
+}
+
+func (s OpentelemetrySpan) AddEvent(name string, attrs ...attribute.KeyValue) {
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+func (s OpentelemetrySpan) SpanContext() trace.SpanContext {
+	return s.span.SpanContext()
+}
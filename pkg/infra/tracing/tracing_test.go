@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSplitAndTrim(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty string", input: "", want: []string{}},
+		{name: "single value", input: "tracecontext", want: []string{"tracecontext"}},
+		{name: "multiple values", input: "tracecontext,baggage", want: []string{"tracecontext", "baggage"}},
+		{name: "whitespace and trailing comma", input: " tracecontext , baggage ,", want: []string{"tracecontext", "baggage"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitAndTrim(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitAndTrim(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("splitAndTrim(%q) = %v, want %v", tt.input, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSampler(t *testing.T) {
+	tests := []struct {
+		name            string
+		sampler         string
+		samplerParam    float64
+		wantDescription string
+	}{
+		{name: "always_on", sampler: "always_on", wantDescription: "AlwaysOnSampler"},
+		{name: "empty defaults to always_on", sampler: "", wantDescription: "AlwaysOnSampler"},
+		{name: "always_off", sampler: "always_off", wantDescription: "AlwaysOffSampler"},
+		{name: "traceidratio", sampler: "traceidratio", samplerParam: 0.5, wantDescription: "TraceIDRatioBased"},
+		{name: "parentbased_traceidratio", sampler: "parentbased_traceidratio", samplerParam: 0.25, wantDescription: "ParentBased"},
+		{name: "unknown falls back to always_on", sampler: "bogus", wantDescription: "AlwaysOnSampler"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TracingService{log: log.New("test"), sampler: tt.sampler, samplerParam: tt.samplerParam}
+			got := ts.buildSampler()
+			if !strings.Contains(got.Description(), tt.wantDescription) {
+				t.Errorf("buildSampler() description = %q, want substring %q", got.Description(), tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestBuildPropagator(t *testing.T) {
+	tests := []struct {
+		name        string
+		propagators []string
+		wantFields  []string
+		wantAbsent  []string
+	}{
+		{name: "tracecontext only", propagators: []string{"tracecontext"}, wantFields: []string{"traceparent"}, wantAbsent: []string{"baggage"}},
+		{name: "tracecontext and baggage", propagators: []string{"tracecontext", "baggage"}, wantFields: []string{"traceparent", "baggage"}},
+		{name: "b3", propagators: []string{"b3"}, wantFields: []string{"b3"}},
+		{name: "jaeger", propagators: []string{"jaeger"}, wantFields: []string{"uber-trace-id"}},
+		{name: "empty falls back to tracecontext+baggage", propagators: nil, wantFields: []string{"traceparent", "baggage"}},
+		{name: "unknown entries are ignored, not fatal", propagators: []string{"bogus"}, wantFields: []string{"traceparent", "baggage"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TracingService{log: log.New("test"), propagators: tt.propagators}
+			got := ts.buildPropagator()
+			fields := got.Fields()
+			for _, want := range tt.wantFields {
+				found := false
+				for _, f := range fields {
+					if f == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("buildPropagator().Fields() = %v, want to contain %q", fields, want)
+				}
+			}
+			for _, notWant := range tt.wantAbsent {
+				for _, f := range fields {
+					if f == notWant {
+						t.Errorf("buildPropagator().Fields() = %v, want to not contain %q", fields, notWant)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHealthTrackingExporterRecordsErrors(t *testing.T) {
+	health := &tracingHealth{}
+	exp := &healthTrackingExporter{name: "test", exp: failingExporter{}, health: health}
+
+	if err := health.Health(); err != nil {
+		t.Fatalf("expected no error before any export, got %v", err)
+	}
+
+	if err := exp.ExportSpans(context.Background(), nil); err == nil {
+		t.Fatal("expected ExportSpans to return the underlying error")
+	}
+
+	if health.Health() == nil {
+		t.Fatal("expected export failure to be recorded on tracingHealth")
+	}
+}
+
+func TestHealthTrackingExporterNoErrorOnSuccess(t *testing.T) {
+	health := &tracingHealth{}
+	exp := &healthTrackingExporter{name: "test", exp: succeedingExporter{}, health: health}
+
+	if err := exp.ExportSpans(context.Background(), nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if health.Health() != nil {
+		t.Fatalf("expected no error recorded, got %v", health.Health())
+	}
+}
+
+// failingExporter and succeedingExporter are tracesdk.SpanExporter stubs used
+// to exercise healthTrackingExporter's error-recording path without standing
+// up a real backend.
+type failingExporter struct{}
+
+func (failingExporter) ExportSpans(_ context.Context, _ []tracesdk.ReadOnlySpan) error {
+	return errors.New("boom")
+}
+
+func (failingExporter) Shutdown(_ context.Context) error { return nil }
+
+type succeedingExporter struct{}
+
+func (succeedingExporter) ExportSpans(_ context.Context, _ []tracesdk.ReadOnlySpan) error {
+	return nil
+}
+
+func (succeedingExporter) Shutdown(_ context.Context) error { return nil }
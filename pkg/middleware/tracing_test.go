@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// fakeSpan records the calls SpanAttributes makes so tests can assert on them
+// without a real OTel SDK span.
+type fakeSpan struct {
+	attrs  []attribute.KeyValue
+	status codes.Code
+	errs   []error
+}
+
+func (s *fakeSpan) End()                                   {}
+func (s *fakeSpan) SetAttributes(kv ...attribute.KeyValue) { s.attrs = append(s.attrs, kv...) }
+func (s *fakeSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.errs = append(s.errs, err)
+}
+func (s *fakeSpan) SetStatus(code codes.Code, _ string)        { s.status = code }
+func (s *fakeSpan) AddEvent(_ string, _ ...attribute.KeyValue) {}
+func (s *fakeSpan) SpanContext() trace.SpanContext             { return trace.SpanContext{} }
+
+func (s *fakeSpan) attr(key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+type fakeTracer struct {
+	span *fakeSpan
+}
+
+func (f *fakeTracer) Start(ctx context.Context, _ string, _ ...trace.SpanStartOption) (context.Context, tracing.Span) {
+	return ctx, f.span
+}
+func (f *fakeTracer) GetTracer(_, _ string) tracing.Tracer { return f }
+func (f *fakeTracer) Health() error                        { return nil }
+func (f *fakeTracer) ForceFlush(_ context.Context) error   { return nil }
+func (f *fakeTracer) Run(_ context.Context) error          { return nil }
+
+func TestSpanAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantError  bool
+	}{
+		{name: "200 OK is not an error", statusCode: http.StatusOK, wantError: false},
+		{name: "404 is not an error", statusCode: http.StatusNotFound, wantError: false},
+		{name: "500 is recorded as an error", statusCode: http.StatusInternalServerError, wantError: true},
+		{name: "503 is recorded as an error", statusCode: http.StatusServiceUnavailable, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := &fakeSpan{}
+			tracer := &fakeTracer{span: span}
+
+			handler := SpanAttributes(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/dashboards/17", nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			gotStatus, ok := span.attr("http.status_code")
+			if !ok || gotStatus.AsInt64() != int64(tt.statusCode) {
+				t.Errorf("http.status_code attribute = %v (ok=%v), want %d", gotStatus, ok, tt.statusCode)
+			}
+
+			if gotTarget, ok := span.attr("http.target"); !ok || gotTarget.AsString() != "/api/dashboards/17" {
+				t.Errorf("http.target attribute = %v (ok=%v), want /api/dashboards/17", gotTarget, ok)
+			}
+
+			if _, ok := span.attr("http.route"); ok {
+				t.Error("http.route should not be set; this middleware has no route template to report")
+			}
+			// No contexthandler.ReqContext is attached to this request (no
+			// auth middleware ran), so user.id/org.id must not appear -
+			// confirming the middleware reads identity from the
+			// authenticated context rather than inventing one.
+			if _, ok := span.attr("user.id"); ok {
+				t.Error("user.id should not be set when there is no authenticated ReqContext")
+			}
+			if _, ok := span.attr("org.id"); ok {
+				t.Error("org.id should not be set when there is no authenticated ReqContext")
+			}
+
+			if tt.wantError {
+				if span.status != codes.Error {
+					t.Errorf("span status = %v, want codes.Error", span.status)
+				}
+				if len(span.errs) == 0 {
+					t.Error("expected RecordError to be called for a 5xx response")
+				}
+			} else {
+				if span.status == codes.Error {
+					t.Errorf("span status = %v, want non-error", span.status)
+				}
+				if len(span.errs) != 0 {
+					t.Errorf("expected no recorded errors, got %v", span.errs)
+				}
+			}
+		})
+	}
+}